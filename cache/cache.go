@@ -0,0 +1,102 @@
+// Package cache provides a concurrent, TTL- and size-bounded in-memory store
+// for rendered stats card bytes. README-embedded badges get hit hard by
+// GitHub's camo proxy, so avoiding a re-render (and re-hitting the GitHub
+// API) for repeat requests of the same card matters a lot here.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry is a single cached payload, its expiry, and its position in the LRU list
+type entry struct {
+	key     string
+	payload []byte
+	expires time.Time
+	elem    *list.Element
+}
+
+// Cache is a concurrent cache bounded by both a per-entry TTL and a maximum
+// entry count, evicting the least-recently-used entry once full.
+type Cache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	items      map[string]*entry
+	order      *list.List // front = most recently used
+}
+
+// New creates a Cache with the given TTL and maximum entry count
+func New(ttl time.Duration, maxEntries int) *Cache {
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		items:      make(map[string]*entry),
+		order:      list.New(),
+	}
+}
+
+// Get returns the cached payload for key, if present and not expired
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+
+	c.order.MoveToFront(e.elem)
+	return e.payload, true
+}
+
+// Set stores payload under key, refreshing its TTL, and evicts the
+// least-recently-used entry if the cache is now over capacity.
+func (c *Cache) Set(key string, payload []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.payload = payload
+		e.expires = time.Now().Add(c.ttl)
+		c.order.MoveToFront(e.elem)
+		return
+	}
+
+	e := &entry{key: key, payload: payload, expires: time.Now().Add(c.ttl)}
+	e.elem = c.order.PushFront(e)
+	c.items[key] = e
+
+	if len(c.items) > c.maxEntries {
+		c.evictOldest()
+	}
+}
+
+// evictOldest removes the least-recently-used entry. Caller must hold c.mu.
+func (c *Cache) evictOldest() {
+	oldest := c.order.Back()
+	if oldest == nil {
+		return
+	}
+
+	e := oldest.Value.(*entry)
+	c.order.Remove(oldest)
+	delete(c.items, e.key)
+}
+
+// Sweep removes all expired entries. Call periodically from a background
+// goroutine to bound memory even for keys that are never looked up again.
+func (c *Cache) Sweep() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, e := range c.items {
+		if now.After(e.expires) {
+			c.order.Remove(e.elem)
+			delete(c.items, key)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetSetRoundTrip(t *testing.T) {
+	c := New(time.Hour, 10)
+
+	if _, ok := c.Get("missing"); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+
+	c.Set("key", []byte("payload"))
+
+	payload, ok := c.Get("key")
+	if !ok {
+		t.Fatal("expected a hit after Set")
+	}
+	if string(payload) != "payload" {
+		t.Fatalf("got payload %q, want %q", payload, "payload")
+	}
+}
+
+func TestGetExpiresAfterTTL(t *testing.T) {
+	c := New(10*time.Millisecond, 10)
+	c.Set("key", []byte("payload"))
+
+	if _, ok := c.Get("key"); !ok {
+		t.Fatal("expected a hit immediately after Set")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Get("key"); ok {
+		t.Fatal("expected a miss once the entry's TTL has elapsed")
+	}
+}
+
+func TestSetEvictsLeastRecentlyUsed(t *testing.T) {
+	c := New(time.Hour, 2)
+
+	c.Set("a", []byte("1"))
+	c.Set("b", []byte("2"))
+	// touch "a" so "b" becomes the least-recently-used entry
+	c.Get("a")
+
+	c.Set("c", []byte("3"))
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected \"b\" to have been evicted as least-recently-used")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected \"a\" to survive eviction since it was used more recently")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Fatal("expected \"c\" to be present as the most recent insert")
+	}
+}
+
+func TestSetOverwritesExistingEntry(t *testing.T) {
+	c := New(time.Hour, 10)
+
+	c.Set("key", []byte("old"))
+	c.Set("key", []byte("new"))
+
+	payload, ok := c.Get("key")
+	if !ok {
+		t.Fatal("expected a hit after overwriting")
+	}
+	if string(payload) != "new" {
+		t.Fatalf("got payload %q, want %q", payload, "new")
+	}
+}
+
+func TestSweepRemovesExpiredEntriesOnly(t *testing.T) {
+	c := New(10*time.Millisecond, 10)
+	c.Set("expires", []byte("1"))
+
+	time.Sleep(20 * time.Millisecond)
+	c.Set("fresh", []byte("2"))
+
+	c.Sweep()
+
+	if _, ok := c.items["expires"]; ok {
+		t.Fatal("expected Sweep to remove the expired entry")
+	}
+	if _, ok := c.items["fresh"]; !ok {
+		t.Fatal("expected Sweep to leave the fresh entry in place")
+	}
+}
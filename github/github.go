@@ -0,0 +1,186 @@
+// Package github fetches GitHub profile statistics via the GraphQL v4 API.
+// Unlike the REST /users and /repos endpoints, GraphQL lets us paginate
+// through all of a user's repositories to compute an accurate total star
+// count instead of just the first page.
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// apiURL is a var, not a const, so tests can point it at an httptest server.
+var apiURL = "https://api.github.com/graphql"
+
+const statsQuery = `
+query($login: String!, $cursor: String) {
+  user(login: $login) {
+    name
+    avatarUrl
+    followers { totalCount }
+    following { totalCount }
+    repositories(first: 100, ownerAffiliations: OWNER, after: $cursor) {
+      totalCount
+      pageInfo { hasNextPage endCursor }
+      nodes { stargazerCount }
+    }
+    contributionsCollection {
+      totalCommitContributions
+      totalPullRequestContributions
+    }
+  }
+}
+`
+
+// Stats holds the profile fields we render onto the stats card
+type Stats struct {
+	Name               string
+	AvatarURL          string
+	Followers          int
+	Following          int
+	PublicRepos        int
+	TotalStars         int
+	ContributedCommits int
+	PullRequests       int
+}
+
+// RateLimit reports how much GraphQL quota remained after a FetchStats call
+type RateLimit struct {
+	Remaining int
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type graphQLResponse struct {
+	Data struct {
+		User struct {
+			Name      string `json:"name"`
+			AvatarURL string `json:"avatarUrl"`
+			Followers struct {
+				TotalCount int `json:"totalCount"`
+			} `json:"followers"`
+			Following struct {
+				TotalCount int `json:"totalCount"`
+			} `json:"following"`
+			Repositories struct {
+				TotalCount int `json:"totalCount"`
+				PageInfo   struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Nodes []struct {
+					StargazerCount int `json:"stargazerCount"`
+				} `json:"nodes"`
+			} `json:"repositories"`
+			ContributionsCollection struct {
+				TotalCommitContributions      int `json:"totalCommitContributions"`
+				TotalPullRequestContributions int `json:"totalPullRequestContributions"`
+			} `json:"contributionsCollection"`
+		} `json:"user"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// FetchStats paginates through the GraphQL v4 API to build an accurate Stats
+// for login. Unlike the REST endpoints this replaces, the GraphQL API has no
+// anonymous tier: GITHUB_TOKEN must be set, or GitHub returns 401 on every
+// call. The returned RateLimit reflects the quota remaining after the last
+// page fetched.
+func FetchStats(login string) (*Stats, RateLimit, error) {
+	var stats *Stats
+	var rateLimit RateLimit
+	cursor := ""
+
+	for {
+		resp, remaining, err := doQuery(login, cursor)
+		if err != nil {
+			return nil, rateLimit, err
+		}
+		rateLimit.Remaining = remaining
+
+		if len(resp.Errors) > 0 {
+			return nil, rateLimit, fmt.Errorf("GraphQL error: %s", resp.Errors[0].Message)
+		}
+
+		user := resp.Data.User
+		if stats == nil {
+			stats = &Stats{
+				Name:               user.Name,
+				AvatarURL:          user.AvatarURL,
+				Followers:          user.Followers.TotalCount,
+				Following:          user.Following.TotalCount,
+				PublicRepos:        user.Repositories.TotalCount,
+				ContributedCommits: user.ContributionsCollection.TotalCommitContributions,
+				PullRequests:       user.ContributionsCollection.TotalPullRequestContributions,
+			}
+		}
+
+		for _, repo := range user.Repositories.Nodes {
+			stats.TotalStars += repo.StargazerCount
+		}
+
+		if !user.Repositories.PageInfo.HasNextPage {
+			break
+		}
+		cursor = user.Repositories.PageInfo.EndCursor
+	}
+
+	return stats, rateLimit, nil
+}
+
+// doQuery runs a single paginated page of statsQuery and reports the
+// X-RateLimit-Remaining header alongside the decoded response.
+func doQuery(login, cursor string) (*graphQLResponse, int, error) {
+	var cursorVar interface{}
+	if cursor != "" {
+		cursorVar = cursor
+	}
+
+	body, err := json.Marshal(graphQLRequest{
+		Query: statsQuery,
+		Variables: map[string]interface{}{
+			"login":  login,
+			"cursor": cursorVar,
+		},
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, apiURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("GitHub GraphQL API returned status: %s", resp.Status)
+	}
+
+	remaining, _ := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+
+	var parsed graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, remaining, err
+	}
+
+	return &parsed, remaining, nil
+}
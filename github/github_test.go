@@ -0,0 +1,88 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// graphQLHandler serves one page of statsQuery per call in pages, keyed by
+// request order, so tests can exercise FetchStats's pagination loop.
+func graphQLHandler(t *testing.T, pages []graphQLResponse) http.HandlerFunc {
+	call := 0
+	return func(w http.ResponseWriter, r *http.Request) {
+		if call >= len(pages) {
+			t.Fatalf("unexpected extra request, only %d pages configured", len(pages))
+		}
+		resp := pages[call]
+		call++
+
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFetchStatsPaginatesAcrossPages(t *testing.T) {
+	page1 := graphQLResponse{}
+	page1.Data.User.Name = "Jane Doe"
+	page1.Data.User.AvatarURL = "https://example.com/avatar.png"
+	page1.Data.User.Followers.TotalCount = 10
+	page1.Data.User.Following.TotalCount = 5
+	page1.Data.User.Repositories.TotalCount = 2
+	page1.Data.User.Repositories.PageInfo.HasNextPage = true
+	page1.Data.User.Repositories.PageInfo.EndCursor = "cursor-1"
+	page1.Data.User.Repositories.Nodes = []struct {
+		StargazerCount int `json:"stargazerCount"`
+	}{{StargazerCount: 3}, {StargazerCount: 7}}
+
+	page2 := graphQLResponse{}
+	page2.Data.User.Name = "Jane Doe"
+	page2.Data.User.Repositories.TotalCount = 2
+	page2.Data.User.Repositories.PageInfo.HasNextPage = false
+	page2.Data.User.Repositories.Nodes = []struct {
+		StargazerCount int `json:"stargazerCount"`
+	}{{StargazerCount: 4}}
+
+	server := httptest.NewServer(graphQLHandler(t, []graphQLResponse{page1, page2}))
+	defer server.Close()
+
+	origURL := apiURL
+	apiURL = server.URL
+	defer func() { apiURL = origURL }()
+
+	stats, rateLimit, err := FetchStats("janedoe")
+	if err != nil {
+		t.Fatalf("FetchStats returned an error: %v", err)
+	}
+
+	if stats.TotalStars != 14 {
+		t.Errorf("got TotalStars %d, want 14 (summed across both pages)", stats.TotalStars)
+	}
+	if stats.Name != "Jane Doe" {
+		t.Errorf("got Name %q, want %q", stats.Name, "Jane Doe")
+	}
+	if rateLimit.Remaining != 4999 {
+		t.Errorf("got rateLimit.Remaining %d, want 4999", rateLimit.Remaining)
+	}
+}
+
+func TestFetchStatsReturnsGraphQLErrors(t *testing.T) {
+	resp := graphQLResponse{}
+	resp.Errors = []struct {
+		Message string `json:"message"`
+	}{{Message: "Could not resolve to a User"}}
+
+	server := httptest.NewServer(graphQLHandler(t, []graphQLResponse{resp}))
+	defer server.Close()
+
+	origURL := apiURL
+	apiURL = server.URL
+	defer func() { apiURL = origURL }()
+
+	if _, _, err := FetchStats("doesnotexist"); err == nil {
+		t.Fatal("expected an error when the GraphQL response contains Errors")
+	}
+}
@@ -0,0 +1,112 @@
+// Package initials renders a colored circular fallback avatar for users
+// whose GitHub profile photo could not be fetched.
+package initials
+
+import (
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// palette is the fixed set of background colors initials avatars are drawn
+// from. The background is picked deterministically per-username so the same
+// user always renders with the same color.
+var palette = []color.Color{
+	color.RGBA{R: 244, G: 63, B: 94, A: 255},  // rose
+	color.RGBA{R: 234, G: 88, B: 12, A: 255},  // orange
+	color.RGBA{R: 202, G: 138, B: 4, A: 255},  // amber
+	color.RGBA{R: 22, G: 163, B: 74, A: 255},  // green
+	color.RGBA{R: 13, G: 148, B: 136, A: 255}, // teal
+	color.RGBA{R: 37, G: 99, B: 235, A: 255},  // blue
+	color.RGBA{R: 124, G: 58, B: 237, A: 255}, // violet
+	color.RGBA{R: 219, G: 39, B: 119, A: 255}, // pink
+}
+
+// Generate draws a filled circle of the requested size, colored
+// deterministically from username, with 1-2 initials derived from
+// displayName centered on top in face. face may be nil, in which case only
+// the colored circle is drawn.
+func Generate(username, displayName string, size int, face font.Face) image.Image {
+	bg := palette[fnv32(username)%uint32(len(palette))]
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	drawCircle(img, bg, size)
+
+	text := Initials(displayName)
+	if face != nil && text != "" {
+		drawCenteredText(img, text, face, size)
+	}
+
+	return img
+}
+
+// Initials derives up to two uppercase initials from name, splitting on
+// spaces and hyphens (e.g. "Jane Doe" -> "JD", "mary-jane" -> "MJ").
+func Initials(name string) string {
+	fields := strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '-'
+	})
+
+	var out []rune
+	for _, f := range fields {
+		if len(out) == 2 {
+			break
+		}
+		for _, r := range f {
+			out = append(out, unicode.ToUpper(r))
+			break
+		}
+	}
+
+	return string(out)
+}
+
+// fnv32 hashes s to a uint32, used to deterministically pick a palette color
+func fnv32(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
+
+// drawCircle fills a size x size image with a solid circle of color c
+func drawCircle(img *image.RGBA, c color.Color, size int) {
+	radius := float64(size) / 2
+	center := radius
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx := float64(x) + 0.5 - center
+			dy := float64(y) + 0.5 - center
+			if dx*dx+dy*dy <= radius*radius {
+				img.Set(x, y, c)
+			}
+		}
+	}
+}
+
+// drawCenteredText draws text centered in a size x size image using face's
+// metrics (ascent/descent) and MeasureString to compute exact centering.
+func drawCenteredText(img draw.Image, text string, face font.Face, size int) {
+	advance := font.MeasureString(face, text)
+	metrics := face.Metrics()
+
+	textWidth := advance.Round()
+	textHeight := (metrics.Ascent + metrics.Descent).Round()
+
+	x := (size - textWidth) / 2
+	y := (size-textHeight)/2 + metrics.Ascent.Round()
+
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  &image.Uniform{C: color.White},
+		Face: face,
+		Dot:  fixed.Point26_6{X: fixed.I(x), Y: fixed.I(y)},
+	}
+	d.DrawString(text)
+}
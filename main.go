@@ -2,48 +2,128 @@ package main
 
 import (
 	"bytes"
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"html"
 	"image"
 	"image/color"
+	"image/color/palette"
 	"image/draw"
+	"image/gif"
 
 	// We need to import jpeg and png to allow image.Decode to work with them
 	_ "image/jpeg"
 	"image/png"
 	"io/ioutil"
 	"log"
+	"math"
 	"net/http"
+	"os"
+	"sync"
+	"time"
 
+	"strconv"
+
+	"github.com/AnubhavSingh0708/gh-stats-gif-server/cache"
+	ghapi "github.com/AnubhavSingh0708/gh-stats-gif-server/github"
+	"github.com/AnubhavSingh0708/gh-stats-gif-server/initials"
 	"github.com/valyala/fasthttp"
+	xdraw "golang.org/x/image/draw"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
 	"golang.org/x/image/math/fixed"
+	"golang.org/x/sync/singleflight"
+)
+
+// baseDPI is the DPI used for a scale factor of 1x
+const baseDPI = 72
+
+// GIF animation tuning: ~30 frames at 50ms gives a ~1.5s count-up that reads
+// clearly without bloating the file
+const (
+	gifFrameCount = 30
+	gifFrameDelay = 5 // in 1/100ths of a second, so 5 == 50ms
+)
+
+// initialsAvatarSize is the fallback avatar resolution at scale=1; it's
+// scaled up the same way the rest of the layout is for higher-scale requests
+const initialsAvatarSize = 160
+
+// svgFontFamily is a web-safe monospace stack, so the SVG backend renders
+// identically wherever it's viewed instead of depending on getFontFace
+// finding a server-side TrueType font
+const svgFontFamily = "Consolas, 'Liberation Mono', Menlo, monospace"
+
+// Cache tuning: rendered cards are cached for an hour by default (override
+// with CACHE_TTL, e.g. "30m"), bounded to 1000 entries via LRU eviction, with
+// a periodic sweep to reclaim memory from keys nobody looks up again.
+const (
+	defaultCacheTTL        = time.Hour
+	defaultCacheMaxEntries = 1000
+	cacheSweepInterval     = 5 * time.Minute
 )
 
-// GitHubUser holds the stats we care about from the GitHub API /users endpoint
-type GitHubUser struct {
-	Name        string `json:"name"`
-	AvatarURL   string `json:"avatar_url"`
-	Followers   int    `json:"followers"`
-	Following   int    `json:"following"`
-	PublicRepos int    `json:"public_repos"`
-	ReposURL    string `json:"repos_url"`
+// statsCache holds rendered card bytes keyed by id|theme|format|w|h|scale|initials
+var statsCache = cache.New(cacheTTLFromEnv(), defaultCacheMaxEntries)
+
+// renderGroup collapses concurrent requests for the same card into a single
+// GitHub round-trip and render, so a burst of camo-proxy hits doesn't hammer
+// the GitHub API or redo the same work.
+var renderGroup singleflight.Group
+
+// cacheTTLFromEnv reads CACHE_TTL (a duration string like "30m" or "2h"),
+// falling back to defaultCacheTTL if it's unset or invalid.
+func cacheTTLFromEnv() time.Duration {
+	raw := os.Getenv("CACHE_TTL")
+	if raw == "" {
+		return defaultCacheTTL
+	}
+
+	ttl, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("Warning: invalid CACHE_TTL %q, using default of %s", raw, defaultCacheTTL)
+		return defaultCacheTTL
+	}
+
+	return ttl
+}
+
+// renderResult is what a singleflight-coalesced render produces: the bytes
+// to serve, and (only when this call actually hit GitHub) the rate limit
+// remaining for the X-RateLimit-Remaining header. rateLimitKnown is tracked
+// separately from rateLimitRemaining since 0 remaining is a legitimate,
+// meaningful value that a cache hit's zero-value result must not be confused
+// with.
+type renderResult struct {
+	payload            []byte
+	rateLimitRemaining int
+	rateLimitKnown     bool
 }
 
-// GitHubRepo is a single repo, used for counting stars
-type GitHubRepo struct {
-	StargazersCount int `json:"stargazers_count"`
+// statusError lets renderCardCached report the HTTP status a failure should
+// map to, since it runs inside a singleflight.Do closure that can only
+// return a plain error.
+type statusError struct {
+	status int
+	err    error
 }
 
+func (e *statusError) Error() string { return e.err.Error() }
+func (e *statusError) Unwrap() error { return e.err }
+
 // StatsData holds all the final info we want to draw
 type StatsData struct {
-	Name        string
-	Avatar      image.Image
-	Followers   int
-	Following   int
-	PublicRepos int
-	TotalStars  int
+	Name               string
+	Avatar             image.Image
+	Followers          int
+	Following          int
+	PublicRepos        int
+	TotalStars         int
+	ContributedCommits int
+	PullRequests       int
 }
 
 // Theme defines the colors for the image
@@ -77,14 +157,40 @@ var themes = map[string]Theme{
 	},
 }
 
-// Font cache for common font sizes
-var fontCache = make(map[int]font.Face)
+// RenderOptions controls the pixel dimensions and DPI of the generated card.
+// Scale lets callers request retina-quality output (e.g. 2x/3x) for HiDPI
+// displays without distorting the layout, since width/height/DPI all scale together.
+type RenderOptions struct {
+	Width  int
+	Height int
+	Scale  float64
+}
+
+// defaultRenderOptions is the 1x card size used when no w/h/scale params are given
+var defaultRenderOptions = RenderOptions{Width: 1200, Height: 800, Scale: 1}
+
+// fontCacheKey lets us cache font faces per (size, DPI) pair, since the same
+// point size renders at different pixel sizes depending on the requested scale
+type fontCacheKey struct {
+	size int
+	dpi  float64
+}
+
+// Font cache for common (size, DPI) pairs. fasthttp invokes requestHandler
+// concurrently per connection, so this is guarded by fontCacheMu rather than
+// relying on single-threaded access.
+var (
+	fontCacheMu sync.RWMutex
+	fontCache   = make(map[fontCacheKey]font.Face)
+)
 
 // requestHandler is the main entry point for all server requests
 func requestHandler(ctx *fasthttp.RequestCtx) {
 	// 1. Parse query parameters
 	githubIDBytes := ctx.QueryArgs().Peek("id")
 	themeNameBytes := ctx.QueryArgs().Peek("theme")
+	format := string(ctx.QueryArgs().Peek("format"))
+	forceInitials := string(ctx.QueryArgs().Peek("avatar")) == "initials"
 
 	if len(githubIDBytes) == 0 {
 		ctx.Error("Missing 'id' query parameter", fasthttp.StatusBadRequest)
@@ -100,95 +206,174 @@ func requestHandler(ctx *fasthttp.RequestCtx) {
 		theme = themes["light"]
 	}
 
-	// 2. Fetch all GitHub stats
-	stats, err := fetchStatsData(githubID)
+	opts := parseRenderOptions(ctx)
+	contentType := contentTypeForFormat(format)
+
+	// 2. Fetch stats + render the card, from cache if we can. The cache key
+	// covers every RenderOptions field plus forceInitials, since each changes
+	// the rendered payload for the same id/theme/format - two requests that
+	// differ in any of them must not collide on the same cache entry.
+	cacheKey := fmt.Sprintf("%s|%s|%s|%d|%d|%g|%t", githubID, themeName, format, opts.Width, opts.Height, opts.Scale, forceInitials)
+
+	result, err := renderCardCached(cacheKey, githubID, format, forceInitials, theme, opts)
 	if err != nil {
-		ctx.Error(fmt.Sprintf("Failed to get GitHub stats for %s: %v", githubID, err), fasthttp.StatusNotFound)
+		status := fasthttp.StatusInternalServerError
+		var se *statusError
+		if errors.As(err, &se) {
+			status = se.status
+		}
+		ctx.Error(err.Error(), status)
 		return
 	}
 
-	// 3. Create the stats image
-	imgBuf, err := createStatsImage(stats, theme)
-	if err != nil {
-		ctx.Error(fmt.Sprintf("Failed to create image: %v", err), fasthttp.StatusInternalServerError)
+	// 3. Honor conditional requests so repeat camo-proxy hits can 304 instead
+	// of re-transferring the whole card
+	etag := etagFor(result.payload)
+	if string(ctx.Request.Header.Peek("If-None-Match")) == etag {
+		ctx.SetStatusCode(fasthttp.StatusNotModified)
 		return
 	}
 
 	// 4. Serve the image
-	ctx.SetContentType("image/png")
+	ctx.SetContentType(contentType)
 	// Set caching headers. This is IMPORTANT to avoid hitting API rate limits.
 	// Cache for 1 hour in browser (max-age) and on CDNs/proxies (s-maxage)
 	ctx.Response.Header.Set("Cache-Control", "public, max-age=3600, s-maxage=3600")
-	ctx.Write(imgBuf.Bytes())
+	ctx.Response.Header.Set("ETag", etag)
+	// Surface remaining GraphQL quota so clients can tell how close we are to
+	// being rate limited by GitHub. Only set on a real GitHub round-trip;
+	// omitted on a cache hit since no quota was spent.
+	if result.rateLimitKnown {
+		ctx.Response.Header.Set("X-RateLimit-Remaining", strconv.Itoa(result.rateLimitRemaining))
+	}
+	ctx.Write(result.payload)
 }
 
-// fetchStatsData orchestrates all the API calls
-func fetchStatsData(username string) (*StatsData, error) {
-	// 1. Get primary user data
-	user, err := getUserData(username)
-	if err != nil {
-		return nil, fmt.Errorf("could not get user data: %w", err)
+// contentTypeForFormat maps a ?format= query value to its response MIME type
+func contentTypeForFormat(format string) string {
+	switch format {
+	case "gif":
+		return "image/gif"
+	case "svg":
+		return "image/svg+xml"
+	default:
+		return "image/png"
 	}
+}
 
-	// 2. Get the avatar image
-	avatar, err := getAvatar(user.AvatarURL)
+// renderCardCached serves cacheKey from statsCache if present, otherwise
+// fetches stats and renders the card, storing the result for next time.
+// Concurrent calls for the same cacheKey are coalesced via renderGroup so
+// only one actually hits GitHub and renders.
+func renderCardCached(cacheKey, githubID, format string, forceInitials bool, theme Theme, opts RenderOptions) (renderResult, error) {
+	v, err, _ := renderGroup.Do(cacheKey, func() (interface{}, error) {
+		if cached, ok := statsCache.Get(cacheKey); ok {
+			return renderResult{payload: cached}, nil
+		}
+
+		stats, rateLimitRemaining, err := fetchStatsData(githubID, forceInitials, opts)
+		if err != nil {
+			return nil, &statusError{status: fasthttp.StatusNotFound, err: fmt.Errorf("failed to get GitHub stats for %s: %w", githubID, err)}
+		}
+
+		var imgBuf *bytes.Buffer
+		switch format {
+		case "gif":
+			imgBuf, err = createStatsGIF(stats, theme, opts)
+		case "svg":
+			imgBuf, err = createStatsSVG(stats, theme, opts)
+		default:
+			imgBuf, err = createStatsImage(stats, theme, opts)
+		}
+		if err != nil {
+			return nil, &statusError{status: fasthttp.StatusInternalServerError, err: fmt.Errorf("failed to create image: %w", err)}
+		}
+
+		payload := imgBuf.Bytes()
+		statsCache.Set(cacheKey, payload)
+		return renderResult{payload: payload, rateLimitRemaining: rateLimitRemaining, rateLimitKnown: true}, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("could not get avatar: %w", err)
+		return renderResult{}, err
 	}
 
-	// 3. Get total stars
-	totalStars, err := getTotalStars(user.ReposURL)
-	if err != nil {
-		// Don't fail the whole request, just set stars to 0
-		log.Printf("Warning: could not get stars for %s: %v", username, err)
-		totalStars = 0
+	return v.(renderResult), nil
+}
+
+// etagFor derives a strong ETag from a sha256 digest of payload
+func etagFor(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// parseRenderOptions reads the w, h, and scale query parameters, falling back
+// to defaultRenderOptions for any that are missing or invalid. scale is
+// applied on top of w/h so a client can request a 2x/3x retina card with
+// ?scale=2 alone, or pin an exact pixel size with w/h.
+func parseRenderOptions(ctx *fasthttp.RequestCtx) RenderOptions {
+	opts := defaultRenderOptions
+
+	if wBytes := ctx.QueryArgs().Peek("w"); len(wBytes) > 0 {
+		if w, err := strconv.Atoi(string(wBytes)); err == nil && w > 0 {
+			opts.Width = w
+		}
 	}
 
-	// Use username as Name if 'name' field is null
-	displayName := user.Name
-	if displayName == "" {
-		displayName = username
+	if hBytes := ctx.QueryArgs().Peek("h"); len(hBytes) > 0 {
+		if h, err := strconv.Atoi(string(hBytes)); err == nil && h > 0 {
+			opts.Height = h
+		}
 	}
 
-	// 4. Assemble final data
-	stats := &StatsData{
-		Name:        displayName,
-		Avatar:      avatar,
-		Followers:   user.Followers,
-		Following:   user.Following,
-		PublicRepos: user.PublicRepos,
-		TotalStars:  totalStars,
+	if scaleBytes := ctx.QueryArgs().Peek("scale"); len(scaleBytes) > 0 {
+		if scale, err := strconv.ParseFloat(string(scaleBytes), 64); err == nil && scale > 0 {
+			opts.Scale = scale
+		}
 	}
 
-	return stats, nil
+	return opts
 }
 
-// getUserData fetches primary user data from the GitHub API
-func getUserData(username string) (*GitHubUser, error) {
-	apiURL := "https://api.github.com/users/" + username
-
-	resp, err := http.Get(apiURL)
+// fetchStatsData orchestrates all the API calls. It returns the GraphQL rate
+// limit remaining alongside the stats so callers can surface it to clients,
+// even when an error is also returned. If forceInitials is set, or the real
+// avatar can't be fetched, it falls back to a generated initials avatar
+// instead of failing the whole request.
+func fetchStatsData(username string, forceInitials bool, opts RenderOptions) (*StatsData, int, error) {
+	// 1. Get accurate profile + star stats via the GraphQL v4 API
+	ghStats, rateLimit, err := ghapi.FetchStats(username)
 	if err != nil {
-		return nil, err
+		return nil, rateLimit.Remaining, fmt.Errorf("could not get user data: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status: %s", resp.Status)
+	// Use username as Name if 'name' field is null
+	displayName := ghStats.Name
+	if displayName == "" {
+		displayName = username
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	// 2. Get the avatar image, falling back to generated initials on failure
+	var avatar image.Image
+	if forceInitials {
+		avatar = generateInitialsAvatar(username, displayName, opts)
+	} else if avatar, err = getAvatar(ghStats.AvatarURL); err != nil {
+		log.Printf("Warning: could not get avatar for %s, falling back to initials: %v", username, err)
+		avatar = generateInitialsAvatar(username, displayName, opts)
 	}
 
-	var user GitHubUser
-	err = json.Unmarshal(body, &user)
-	if err != nil {
-		return nil, err
+	// 3. Assemble final data
+	stats := &StatsData{
+		Name:               displayName,
+		Avatar:             avatar,
+		Followers:          ghStats.Followers,
+		Following:          ghStats.Following,
+		PublicRepos:        ghStats.PublicRepos,
+		TotalStars:         ghStats.TotalStars,
+		ContributedCommits: ghStats.ContributedCommits,
+		PullRequests:       ghStats.PullRequests,
 	}
 
-	return &user, nil
+	return stats, rateLimit.Remaining, nil
 }
 
 // getAvatar fetches the user's profile picture
@@ -212,83 +397,39 @@ func getAvatar(url string) (image.Image, error) {
 	return avatar, nil
 }
 
-// getTotalStars fetches all repos (up to 100) and sums their stars
-func getTotalStars(reposURL string) (int, error) {
-	// Get first 100 repos. For a user with > 100, we'd need to handle pagination
-	// This is a good-enough approximation for this app.
-	apiURL := reposURL + "?per_page=100"
-
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		return 0, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("repos API returned status: %s", resp.Status)
-	}
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return 0, err
-	}
-
-	var repos []GitHubRepo
-	err = json.Unmarshal(body, &repos)
-	if err != nil {
-		return 0, err
-	}
-
-	totalStars := 0
-	for _, repo := range repos {
-		totalStars += repo.StargazersCount
-	}
-
-	return totalStars, nil
+// generateInitialsAvatar builds a fallback avatar from the user's initials,
+// rendered directly at the requested output scale. Unlike a fetched avatar,
+// which is a fixed-resolution image we have to resize after the fact, we
+// control this rendering ourselves, so drawing it at the target size/DPI up
+// front keeps the initials text crisp instead of getting blurry via
+// resizeImage's upscaling on high-scale requests.
+func generateInitialsAvatar(username, displayName string, opts RenderOptions) image.Image {
+	scale := normalizeScale(opts.Scale)
+	size := scaled(initialsAvatarSize, scale)
+	fontSize := int(float64(size) * 0.4)
+	face := getFontFace(fontSize, baseDPI*scale)
+	return initials.Generate(username, displayName, size, face)
 }
 
-// resizeImage resizes an image to the specified width and height by sampling pixels
-// This uses nearest-neighbor sampling to preserve the image quality
+// resizeImage resizes an image to the specified width and height using
+// Catmull-Rom resampling, which produces much crisper results than
+// nearest-neighbor sampling, especially for avatars rendered at retina sizes.
 func resizeImage(src image.Image, width, height int) image.Image {
-	// Get the source image bounds
-	srcBounds := src.Bounds()
-	srcWidth := srcBounds.Max.X - srcBounds.Min.X
-	srcHeight := srcBounds.Max.Y - srcBounds.Min.Y
-
-	// Create a new RGBA image with the target dimensions
 	dst := image.NewRGBA(image.Rect(0, 0, width, height))
-
-	// Calculate the scaling factors
-	xRatio := float64(srcWidth) / float64(width)
-	yRatio := float64(srcHeight) / float64(height)
-
-	// Sample pixels from the source image and fill the destination
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			// Calculate the source coordinates by sampling proportionally
-			srcX := int(float64(x) * xRatio)
-			srcY := int(float64(y) * yRatio)
-
-			// Ensure we stay within bounds
-			if srcX >= srcWidth {
-				srcX = srcWidth - 1
-			}
-			if srcY >= srcHeight {
-				srcY = srcHeight - 1
-			}
-
-			// Get the pixel from the source and set it in the destination
-			r, g, b, a := src.At(srcBounds.Min.X+srcX, srcBounds.Min.Y+srcY).RGBA()
-			dst.SetRGBA(x, y, color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)})
-		}
-	}
-
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), xdraw.Src, nil)
 	return dst
 }
 
-// getFontFace returns a font face for the given size, trying system fonts
-func getFontFace(size int) font.Face {
-	if face, ok := fontCache[size]; ok {
+// getFontFace returns a font face for the given size and DPI, trying system
+// fonts. DPI should scale with the requested output scale (baseDPI * scale)
+// so that text stays crisp instead of just getting blurrier when upscaled.
+func getFontFace(size int, dpi float64) font.Face {
+	key := fontCacheKey{size: size, dpi: dpi}
+
+	fontCacheMu.RLock()
+	face, ok := fontCache[key]
+	fontCacheMu.RUnlock()
+	if ok {
 		return face
 	}
 
@@ -316,13 +457,15 @@ func getFontFace(size int) font.Face {
 
 		face, err := opentype.NewFace(parsedFont, &opentype.FaceOptions{
 			Size: float64(size),
-			DPI:  72,
+			DPI:  dpi,
 		})
 		if err != nil {
 			continue
 		}
 
-		fontCache[size] = face
+		fontCacheMu.Lock()
+		fontCache[key] = face
+		fontCacheMu.Unlock()
 		return face
 	}
 
@@ -331,45 +474,106 @@ func getFontFace(size int) font.Face {
 	return font.Face(nil)
 }
 
-// createStatsImage draws the stats onto a new image and returns the PNG bytes
-func createStatsImage(stats *StatsData, theme Theme) (*bytes.Buffer, error) {
-	// Define image dimensions
-	width := 1200
-	height := 800
-
-	// Create a new RGBA image
-	img := image.NewRGBA(image.Rect(0, 0, width, height))
+// avatarPrimitive places an already-resized avatar image at a position and size
+type avatarPrimitive struct {
+	X, Y, Size int
+	Image      image.Image
+}
 
-	// Fill the background
-	draw.Draw(img, img.Bounds(), &image.Uniform{C: theme.BGColor}, image.Point{}, draw.Src)
+// textPrimitive is a single run of text at a position, color, and font size
+type textPrimitive struct {
+	X, Y     int
+	Text     string
+	Color    color.Color
+	FontSize int
+}
 
-	// --- Draw Avatar ---
-	avatarSize := 160
-	// Create a destination rectangle for the avatar
-	avatarDestRect := image.Rect(53, 53, 53+avatarSize, 53+avatarSize)
-	// Resize the avatar by sampling pixels proportionally from the source image
-	resizedAvatar := resizeImage(stats.Avatar, avatarSize, avatarSize)
-	// Draw the resized avatar into the destination rectangle
-	draw.Draw(img, avatarDestRect, resizedAvatar, image.Point{0, 0}, draw.Src)
+// cardLayout is a format-agnostic description of a stats card: a raster
+// backend turns it into pixels, the SVG backend turns it into markup.
+// Building it once keeps the two backends from drifting apart.
+type cardLayout struct {
+	Width, Height int
+	Background    color.Color
+	Avatar        avatarPrimitive
+	Texts         []textPrimitive
+	// TextBounds generously covers every Texts primitive's pixel footprint.
+	// The GIF backend uses it to crop delta frames to just the region that
+	// actually changes between frames, leaving the avatar and background
+	// untouched rather than re-rendering and re-quantizing the whole canvas.
+	TextBounds image.Rectangle
+}
 
-	// --- Draw Text ---
-	// NOTE: Using system TrueType fonts for larger sizes
+// buildCardLayout computes the primitives for a stats card at the given
+// progress (0 = counters at zero, 1 = final values) and titleColor. progress
+// and titleColor let the GIF backend animate the counters and cycle the
+// title hue; the PNG and SVG backends always pass progress=1 and theme.TitleColor.
+func buildCardLayout(stats *StatsData, theme Theme, opts RenderOptions, resizedAvatar image.Image, titleColor color.Color, progress float64) cardLayout {
+	scale := normalizeScale(opts.Scale)
+	avatarSize := scaled(160, scale)
+	avatarOrigin := scaled(53, scale)
+
+	yPos := scaled(293, scale) // Start below the avatar
+	statSpacing := scaled(67, scale)
+	xPos1 := scaled(53, scale)
+	xPos2 := scaled(640, scale) // Start of second column
+
+	followers := int(float64(stats.Followers) * progress)
+	following := int(float64(stats.Following) * progress)
+	publicRepos := int(float64(stats.PublicRepos) * progress)
+	totalStars := int(float64(stats.TotalStars) * progress)
+	commits := int(float64(stats.ContributedCommits) * progress)
+	pullRequests := int(float64(stats.PullRequests) * progress)
+
+	texts := []textPrimitive{
+		{X: avatarOrigin + avatarSize + avatarOrigin, Y: scaled(147, scale), Text: stats.Name, Color: titleColor, FontSize: 72},
+		{X: xPos1, Y: yPos, Text: fmt.Sprintf("Followers: %d", followers), Color: theme.StatsColor, FontSize: 48},
+		{X: xPos2, Y: yPos, Text: fmt.Sprintf("Following: %d", following), Color: theme.StatsColor, FontSize: 48},
+		{X: xPos1, Y: yPos + statSpacing, Text: fmt.Sprintf("Public Repos: %d", publicRepos), Color: theme.StatsColor, FontSize: 48},
+		{X: xPos2, Y: yPos + statSpacing, Text: fmt.Sprintf("Total Stars: %d", totalStars), Color: theme.StatsColor, FontSize: 48},
+		{X: xPos1, Y: yPos + 2*statSpacing, Text: fmt.Sprintf("Commits: %d", commits), Color: theme.StatsColor, FontSize: 48},
+		{X: xPos2, Y: yPos + 2*statSpacing, Text: fmt.Sprintf("Pull Requests: %d", pullRequests), Color: theme.StatsColor, FontSize: 48},
+	}
 
-	// Draw the Name, vertically centered with the avatar
-	addLabel(img, 53+avatarSize+53, 147, stats.Name, theme.TitleColor, 72)
+	return cardLayout{
+		Width:      scaled(opts.Width, scale),
+		Height:     scaled(opts.Height, scale),
+		Background: theme.BGColor,
+		Avatar:     avatarPrimitive{X: avatarOrigin, Y: avatarOrigin, Size: avatarSize, Image: resizedAvatar},
+		Texts:      texts,
+		TextBounds: textBounds(texts, scale),
+	}
+}
 
-	// --- Draw the stats in two columns ---
-	yPos := 293 // Start below the avatar
-	statSpacing := 67
-	xPos1 := 53
-	xPos2 := 640 // Start of second column
+// textRect returns a rectangle generously covering t's pixel footprint at the
+// given scale (padded for ascent/descent/kerning rather than measured
+// exactly). FontSize is in points at baseDPI, so it renders at FontSize*scale
+// pixels once the DPI is scaled.
+func textRect(t textPrimitive, scale float64) image.Rectangle {
+	px := float64(t.FontSize) * scale
+	width := int(float64(len(t.Text)) * px * 0.65)
+	return image.Rect(t.X, t.Y-int(px*0.85), t.X+width, t.Y+int(px*0.35))
+}
 
-	addLabel(img, xPos1, yPos, fmt.Sprintf("Followers: %d", stats.Followers), theme.StatsColor, 48)
-	addLabel(img, xPos2, yPos, fmt.Sprintf("Following: %d", stats.Following), theme.StatsColor, 48)
+// textBounds returns the union of every text primitive's textRect, used to
+// crop GIF delta frames to just the region that changes between frames.
+func textBounds(texts []textPrimitive, scale float64) image.Rectangle {
+	var b image.Rectangle
+	for i, t := range texts {
+		r := textRect(t, scale)
+		if i == 0 {
+			b = r
+		} else {
+			b = b.Union(r)
+		}
+	}
+	return b
+}
 
-	yPos += statSpacing
-	addLabel(img, xPos1, yPos, fmt.Sprintf("Public Repos: %d", stats.PublicRepos), theme.StatsColor, 48)
-	addLabel(img, xPos2, yPos, fmt.Sprintf("Total Stars: %d", stats.TotalStars), theme.StatsColor, 48)
+// createStatsImage draws the stats onto a new image and returns the PNG bytes.
+func createStatsImage(stats *StatsData, theme Theme, opts RenderOptions) (*bytes.Buffer, error) {
+	resizedAvatar := resizeImage(stats.Avatar, scaled(160, normalizeScale(opts.Scale)), scaled(160, normalizeScale(opts.Scale)))
+	layout := buildCardLayout(stats, theme, opts, resizedAvatar, theme.TitleColor, 1)
+	img := renderRaster(layout, baseDPI*normalizeScale(opts.Scale))
 
 	// --- Encode to PNG ---
 	buf := new(bytes.Buffer)
@@ -381,9 +585,294 @@ func createStatsImage(stats *StatsData, theme Theme) (*bytes.Buffer, error) {
 	return buf, nil
 }
 
-// addLabel is a helper function to draw text on the image with specified font size
-func addLabel(img draw.Image, x, y int, label string, clr color.Color, fontSize int) {
-	face := getFontFace(fontSize)
+// createStatsSVG renders the stats card as an SVG document, which embeds
+// the (already-rasterized) avatar inline and draws everything else as
+// vector text, so it stays crisp at any size without needing a server font.
+func createStatsSVG(stats *StatsData, theme Theme, opts RenderOptions) (*bytes.Buffer, error) {
+	resizedAvatar := resizeImage(stats.Avatar, scaled(160, normalizeScale(opts.Scale)), scaled(160, normalizeScale(opts.Scale)))
+	layout := buildCardLayout(stats, theme, opts, resizedAvatar, theme.TitleColor, 1)
+	return renderSVG(layout, normalizeScale(opts.Scale))
+}
+
+// createStatsGIF renders an animated GIF that counts the stat numbers
+// up from 0 to their final values while cycling the title color through a
+// hue rotation. The background and avatar are drawn only once, as the first
+// frame's base layer; every later frame is cropped to layout.TextBounds and
+// drawn with gif.DisposalNone over a transparent palette entry, so the
+// avatar never gets redrawn (and re-dithered) on every tick.
+func createStatsGIF(stats *StatsData, theme Theme, opts RenderOptions) (*bytes.Buffer, error) {
+	avatarSize := scaled(160, normalizeScale(opts.Scale))
+	resizedAvatar := resizeImage(stats.Avatar, avatarSize, avatarSize)
+	gifPalette := buildPalette(theme)
+	dpi := baseDPI * normalizeScale(opts.Scale)
+
+	anim := &gif.GIF{}
+	for i := 0; i < gifFrameCount; i++ {
+		progress := easeOut(float64(i+1) / float64(gifFrameCount))
+		hue := 360 * float64(i) / float64(gifFrameCount)
+		titleColor := rotateHue(theme.TitleColor, hue)
+
+		layout := buildCardLayout(stats, theme, opts, resizedAvatar, titleColor, progress)
+
+		var frame *image.Paletted
+		if i == 0 {
+			frame = drawBaseFrame(layout, dpi, gifPalette)
+		} else {
+			frame = drawDeltaFrame(layout, dpi, gifPalette)
+		}
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, gifFrameDelay)
+		anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := gif.EncodeAll(buf, anim); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// drawBaseFrame rasterizes the full card (background, avatar, and text) and
+// quantizes it against gifPalette. This is only ever called for frame 0;
+// gif.DisposalNone keeps it on screen underneath every later delta frame.
+func drawBaseFrame(layout cardLayout, dpi float64, gifPalette color.Palette) *image.Paletted {
+	img := renderRaster(layout, dpi)
+
+	paletted := image.NewPaletted(img.Bounds(), gifPalette)
+	draw.FloydSteinberg.Draw(paletted, img.Bounds(), img, image.Point{})
+	return paletted
+}
+
+// drawDeltaFrame rasterizes only layout.TextBounds - just the text that
+// changes between frames - onto a transparent canvas, and quantizes it
+// against a copy of gifPalette with a transparent entry added. Each text's
+// own rectangle is first filled with the background, so the previous frame's
+// text underneath is covered rather than showing through the new glyphs.
+// Pixels left transparent (the rest of the crop, including any part of it
+// that happens to fall over the avatar) let the earlier frames' pixels show
+// through unchanged instead of being redrawn and re-dithered every tick.
+func drawDeltaFrame(layout cardLayout, dpi float64, gifPalette color.Palette) *image.Paletted {
+	bounds := layout.TextBounds.Intersect(image.Rect(0, 0, layout.Width, layout.Height))
+	scale := dpi / baseDPI
+
+	img := image.NewRGBA(bounds)
+	for _, t := range layout.Texts {
+		draw.Draw(img, textRect(t, scale), &image.Uniform{C: layout.Background}, image.Point{}, draw.Src)
+	}
+	for _, t := range layout.Texts {
+		addLabel(img, t.X, t.Y, t.Text, t.Color, t.FontSize, dpi)
+	}
+
+	paletted := image.NewPaletted(bounds, deltaPalette(gifPalette))
+	draw.FloydSteinberg.Draw(paletted, bounds, img, bounds.Min)
+	return paletted
+}
+
+// deltaPalette is gifPalette with a fully-transparent entry added, so
+// drawDeltaFrame's untouched pixels (true RGBA zero value) quantize exactly
+// to it rather than to the nearest opaque color.
+func deltaPalette(gifPalette color.Palette) color.Palette {
+	p := make(color.Palette, 0, len(gifPalette)+1)
+	p = append(p, color.RGBA{})
+	p = append(p, gifPalette...)
+	if len(p) > 256 {
+		p = p[:256]
+	}
+	return p
+}
+
+// renderRaster draws a cardLayout's background, avatar, and text primitives
+// onto a new RGBA canvas at the given font DPI.
+func renderRaster(layout cardLayout, dpi float64) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, layout.Width, layout.Height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: layout.Background}, image.Point{}, draw.Src)
+
+	// draw.Over, not draw.Src: the initials fallback avatar is a circle on a
+	// transparent square (drawCircle only fills inside the radius), so Src
+	// would punch a transparent hole in the card's corners instead of
+	// leaving the theme background showing through them.
+	avatar := layout.Avatar
+	avatarDestRect := image.Rect(avatar.X, avatar.Y, avatar.X+avatar.Size, avatar.Y+avatar.Size)
+	draw.Draw(img, avatarDestRect, avatar.Image, image.Point{0, 0}, draw.Over)
+
+	for _, t := range layout.Texts {
+		addLabel(img, t.X, t.Y, t.Text, t.Color, t.FontSize, dpi)
+	}
+
+	return img
+}
+
+// renderSVG turns a cardLayout into an SVG document: the background becomes
+// a <rect>, the avatar an inline base64 <image>, and each text primitive a
+// <text> element set in a web-safe monospace stack. Font sizes are multiplied
+// by scale so text matches the raster backend's scaled DPI.
+func renderSVG(layout cardLayout, scale float64) (*bytes.Buffer, error) {
+	avatarPNG := new(bytes.Buffer)
+	if err := png.Encode(avatarPNG, layout.Avatar.Image); err != nil {
+		return nil, err
+	}
+	avatarDataURI := base64.StdEncoding.EncodeToString(avatarPNG.Bytes())
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		layout.Width, layout.Height, layout.Width, layout.Height)
+	fmt.Fprintf(buf, `<rect width="%d" height="%d" fill="%s"/>`, layout.Width, layout.Height, hexColor(layout.Background))
+
+	avatar := layout.Avatar
+	fmt.Fprintf(buf, `<image x="%d" y="%d" width="%d" height="%d" href="data:image/png;base64,%s"/>`,
+		avatar.X, avatar.Y, avatar.Size, avatar.Size, avatarDataURI)
+
+	for _, t := range layout.Texts {
+		fmt.Fprintf(buf, `<text x="%d" y="%d" font-family="%s" font-size="%d" fill="%s">%s</text>`,
+			t.X, t.Y, svgFontFamily, scaled(t.FontSize, scale), hexColor(t.Color), html.EscapeString(t.Text))
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf, nil
+}
+
+// hexColor formats a color as a "#rrggbb" string for use in SVG attributes
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// scaled multiplies a 1x layout value by the requested scale factor
+func scaled(value int, scale float64) int {
+	return int(float64(value) * scale)
+}
+
+// normalizeScale defaults an unset or invalid scale factor to 1x
+func normalizeScale(scale float64) float64 {
+	if scale <= 0 {
+		return 1
+	}
+	return scale
+}
+
+// easeOut applies an ease-out cubic curve so the count-up animation
+// decelerates into its final value instead of stopping abruptly
+func easeOut(t float64) float64 {
+	inv := 1 - t
+	return 1 - inv*inv*inv
+}
+
+// buildPalette derives a GIF-safe palette from the theme's colors plus a
+// websafe backdrop, so the background and stats text quantize exactly while
+// avatar photos still get reasonable color coverage. The GIF's hue-rotated
+// title colors are not in the palette and quantize to their nearest entry.
+func buildPalette(theme Theme) color.Palette {
+	p := make(color.Palette, 0, len(palette.WebSafe)+4)
+	p = append(p, theme.BGColor, theme.TextColor, theme.TitleColor, theme.StatsColor)
+	p = append(p, palette.WebSafe...)
+	if len(p) > 256 {
+		p = p[:256]
+	}
+	return p
+}
+
+// rotateHue shifts a color's hue by the given number of degrees while
+// preserving its saturation and lightness, used to cycle the GIF title color
+func rotateHue(c color.Color, degrees float64) color.Color {
+	r, g, b, a := c.RGBA()
+	h, s, l := rgbToHSL(uint8(r>>8), uint8(g>>8), uint8(b>>8))
+
+	h = math.Mod(h+degrees, 360)
+	if h < 0 {
+		h += 360
+	}
+
+	nr, ng, nb := hslToRGB(h, s, l)
+	return color.RGBA{R: nr, G: ng, B: nb, A: uint8(a >> 8)}
+}
+
+// rgbToHSL converts 8-bit RGB to hue (degrees), saturation, and lightness
+// (both 0-1)
+func rgbToHSL(r, g, b uint8) (h, s, l float64) {
+	rf := float64(r) / 255
+	gf := float64(g) / 255
+	bf := float64(b) / 255
+
+	max := math.Max(rf, math.Max(gf, bf))
+	min := math.Min(rf, math.Min(gf, bf))
+	l = (max + min) / 2
+
+	if max == min {
+		return 0, 0, l
+	}
+
+	d := max - min
+	if l > 0.5 {
+		s = d / (2 - max - min)
+	} else {
+		s = d / (max + min)
+	}
+
+	switch max {
+	case rf:
+		h = (gf - bf) / d
+		if gf < bf {
+			h += 6
+		}
+	case gf:
+		h = (bf-rf)/d + 2
+	default:
+		h = (rf-gf)/d + 4
+	}
+	h *= 60
+
+	return h, s, l
+}
+
+// hslToRGB converts hue (degrees), saturation, and lightness (both 0-1)
+// back to 8-bit RGB
+func hslToRGB(h, s, l float64) (uint8, uint8, uint8) {
+	if s == 0 {
+		v := uint8(l * 255)
+		return v, v, v
+	}
+
+	var q float64
+	if l < 0.5 {
+		q = l * (1 + s)
+	} else {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+	hk := h / 360
+
+	r := hueToRGBChannel(p, q, hk+1.0/3)
+	g := hueToRGBChannel(p, q, hk)
+	b := hueToRGBChannel(p, q, hk-1.0/3)
+
+	return uint8(r * 255), uint8(g * 255), uint8(b * 255)
+}
+
+// hueToRGBChannel computes a single RGB channel from the p/q intermediates
+// used by hslToRGB
+func hueToRGBChannel(p, q, t float64) float64 {
+	if t < 0 {
+		t++
+	}
+	if t > 1 {
+		t--
+	}
+	switch {
+	case t < 1.0/6:
+		return p + (q-p)*6*t
+	case t < 1.0/2:
+		return q
+	case t < 2.0/3:
+		return p + (q-p)*(2.0/3-t)*6
+	default:
+		return p
+	}
+}
+
+// addLabel is a helper function to draw text on the image with specified font size and DPI
+func addLabel(img draw.Image, x, y int, label string, clr color.Color, fontSize int, dpi float64) {
+	face := getFontFace(fontSize, dpi)
 	if face == nil {
 		log.Printf("Warning: Could not load font, skipping text: %s", label)
 		return
@@ -401,11 +890,33 @@ func addLabel(img draw.Image, x, y int, label string, clr color.Color, fontSize
 
 // main starts the fasthttp server
 func main() {
+	// Unlike the REST endpoints this server used to call, the GraphQL v4 API
+	// has no anonymous tier at all - every request needs a bearer token, or
+	// GitHub returns 401. Fail at startup instead of letting every request
+	// 404 silently.
+	if os.Getenv("GITHUB_TOKEN") == "" {
+		log.Fatal("GITHUB_TOKEN is not set; the GitHub GraphQL API requires authentication on every request")
+	}
+
 	port := ":8800"
 	log.Printf("Starting GitHub stats server on %s...", port)
 
+	// Periodically reclaim memory from cache entries nobody looks up again
+	go sweepCacheForever()
+
 	// Start the server
 	if err := fasthttp.ListenAndServe(port, requestHandler); err != nil {
 		log.Fatalf("Error in ListenAndServe: %v", err)
 	}
 }
+
+// sweepCacheForever runs statsCache.Sweep on a fixed interval for the life
+// of the process
+func sweepCacheForever() {
+	ticker := time.NewTicker(cacheSweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		statsCache.Sweep()
+	}
+}